@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"context"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var _ KeyValueCache = (*BroadcastingKeyValue)(nil)
+
+// BroadcastingKeyValue wraps a local KeyValueCache (typically
+// InMemoryKeyValue) and publishes an InvalidationMessage over an
+// InvalidationBus on every write, so that other instances sharing the same
+// bus can drop their own stale copy instead of waiting out the TTL. Each
+// instance tags its own messages with a random instanceID and ignores
+// messages carrying it back, so a pod never reacts to its own writes.
+type BroadcastingKeyValue struct {
+	local      KeyValueCache
+	bus        InvalidationBus
+	instanceID string
+	prefix     string
+}
+
+// NewBroadcastingKeyValue wraps local, broadcasting every write over bus.
+// Use NoopInvalidationBus for single-node deployments.
+func NewBroadcastingKeyValue(local KeyValueCache, bus InvalidationBus) *BroadcastingKeyValue {
+	return &BroadcastingKeyValue{
+		local:      local,
+		bus:        bus,
+		instanceID: uuid.NewString(),
+	}
+}
+
+// Listen subscribes to bus and applies remote invalidations to the local
+// cache until ctx is done. Callers should run it once, in a background
+// goroutine, for the root BroadcastingKeyValue returned by
+// NewBroadcastingKeyValue (not for values returned by WithPrefix).
+func (b *BroadcastingKeyValue) Listen(ctx context.Context) error {
+	messages, err := b.bus.Subscribe(ctx)
+	if err != nil {
+		return err
+	}
+
+	for msg := range messages {
+		if msg.InstanceID == b.instanceID {
+			continue
+		}
+
+		target := b.local
+		if msg.Prefix != "" {
+			target = b.local.WithPrefix(msg.Prefix)
+		}
+
+		if msg.Tag != "" {
+			_ = target.InvalidateTag(ctx, msg.Tag)
+			continue
+		}
+
+		// A remote Set only means a peer refreshed its own copy - it says
+		// nothing about ours, so only Unset messages evict locally. Without
+		// this, a peer's Set would thrash any cache whose read path re-Sets
+		// on every hit (e.g. a refresh-on-read pattern).
+		if msg.Op != InvalidationOpUnset {
+			continue
+		}
+
+		_ = target.Unset(ctx, msg.Key)
+	}
+
+	return nil
+}
+
+// Get implements KeyValueCache.
+func (b *BroadcastingKeyValue) Get(ctx context.Context, key string) ([]byte, error) {
+	return b.local.Get(ctx, key)
+}
+
+// Set implements KeyValueCache.
+func (b *BroadcastingKeyValue) Set(ctx context.Context, key string, value []byte) error {
+	if err := b.local.Set(ctx, key, value); err != nil {
+		return err
+	}
+
+	b.publish(ctx, key, InvalidationOpSet)
+
+	return nil
+}
+
+// Unset implements KeyValueCache.
+func (b *BroadcastingKeyValue) Unset(ctx context.Context, key string) error {
+	if err := b.local.Unset(ctx, key); err != nil {
+		return err
+	}
+
+	b.publish(ctx, key, InvalidationOpUnset)
+
+	return nil
+}
+
+// WithPrefix implements KeyValueCache.
+func (b *BroadcastingKeyValue) WithPrefix(prefix string) KeyValueCache {
+	return &BroadcastingKeyValue{
+		local:      b.local.WithPrefix(prefix),
+		bus:        b.bus,
+		instanceID: b.instanceID,
+		prefix:     path.Join(b.prefix, prefix),
+	}
+}
+
+// GetOrLock implements KeyValueCache. Locks are process-local and not
+// broadcast - a lock held on one instance doesn't need to be visible to
+// others, since each instance only needs to dedupe its own concurrent misses.
+func (b *BroadcastingKeyValue) GetOrLock(ctx context.Context, key string, lockTTL time.Duration) ([]byte, error) {
+	return b.local.GetOrLock(ctx, key, lockTTL)
+}
+
+// SetWithTags implements KeyValueCache.
+func (b *BroadcastingKeyValue) SetWithTags(ctx context.Context, key string, value []byte, tags []string) error {
+	if err := b.local.SetWithTags(ctx, key, value, tags); err != nil {
+		return err
+	}
+
+	b.publish(ctx, key, InvalidationOpSet)
+
+	return nil
+}
+
+// InvalidateTag implements KeyValueCache.
+func (b *BroadcastingKeyValue) InvalidateTag(ctx context.Context, tag string) error {
+	if err := b.local.InvalidateTag(ctx, tag); err != nil {
+		return err
+	}
+
+	b.publishTag(ctx, tag)
+
+	return nil
+}
+
+func (b *BroadcastingKeyValue) publish(ctx context.Context, key string, op InvalidationOp) {
+	_ = b.bus.Publish(ctx, InvalidationMessage{
+		InstanceID: b.instanceID,
+		Prefix:     b.prefix,
+		Key:        key,
+		Op:         op,
+	})
+}
+
+func (b *BroadcastingKeyValue) publishTag(ctx context.Context, tag string) {
+	_ = b.bus.Publish(ctx, InvalidationMessage{
+		InstanceID: b.instanceID,
+		Prefix:     b.prefix,
+		Tag:        tag,
+		Op:         InvalidationOpUnset,
+	})
+}
@@ -0,0 +1,190 @@
+package cache_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ente-io/museum/ente/cache"
+)
+
+// fakeInvalidationBus is an in-process InvalidationBus fan-out, used to test
+// BroadcastingKeyValue without a real Redis instance.
+type fakeInvalidationBus struct {
+	mu   sync.Mutex
+	subs []chan cache.InvalidationMessage
+}
+
+func (b *fakeInvalidationBus) Publish(_ context.Context, msg cache.InvalidationMessage) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, sub := range b.subs {
+		sub <- msg
+	}
+
+	return nil
+}
+
+func (b *fakeInvalidationBus) Subscribe(ctx context.Context) (<-chan cache.InvalidationMessage, error) {
+	ch := make(chan cache.InvalidationMessage, 16)
+
+	b.mu.Lock()
+	b.subs = append(b.subs, ch)
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+
+	return ch, nil
+}
+
+func Test_BroadcastingKeyValue_RemoteUnsetEvictsLocal(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	bus := &fakeInvalidationBus{}
+
+	a := cache.NewBroadcastingKeyValue(cache.NewInMemoryKeyValue(time.Minute, time.Minute), bus)
+	bNode := cache.NewBroadcastingKeyValue(cache.NewInMemoryKeyValue(time.Minute, time.Minute), bus)
+
+	go a.Listen(ctx)
+	go bNode.Listen(ctx)
+	time.Sleep(10 * time.Millisecond) // let both Listen goroutines subscribe
+
+	if err := a.Set(ctx, "token", []byte("value")); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	// A Set is never replicated to peers, so bNode needs its own copy.
+	if err := bNode.Set(ctx, "token", []byte("value")); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	waitForCondition(t, func() bool {
+		_, err := bNode.Get(ctx, "token")
+		return err == nil
+	})
+
+	if err := a.Unset(ctx, "token"); err != nil {
+		t.Fatalf("Unset: %v", err)
+	}
+
+	waitForCondition(t, func() bool {
+		_, err := bNode.Get(ctx, "token")
+		return errors.Is(err, cache.ErrCacheMiss)
+	})
+}
+
+func Test_BroadcastingKeyValue_RemoteSetDoesNotEvictLocal(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	bus := &fakeInvalidationBus{}
+
+	a := cache.NewBroadcastingKeyValue(cache.NewInMemoryKeyValue(time.Minute, time.Minute), bus)
+	bNode := cache.NewBroadcastingKeyValue(cache.NewInMemoryKeyValue(time.Minute, time.Minute), bus)
+
+	go a.Listen(ctx)
+	go bNode.Listen(ctx)
+	time.Sleep(10 * time.Millisecond)
+
+	if err := bNode.Set(ctx, "token", []byte("local")); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if err := a.Set(ctx, "token", []byte("remote")); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	// Give the remote Set message a chance to be mishandled as an eviction.
+	time.Sleep(20 * time.Millisecond)
+
+	val, err := bNode.Get(ctx, "token")
+	if err != nil {
+		t.Fatalf("expected local copy to survive a peer's Set, got err %v", err)
+	}
+	if !bytes.Equal(val, []byte("local")) {
+		t.Errorf("value %x, expected local copy %x to be untouched", val, []byte("local"))
+	}
+}
+
+func Test_BroadcastingKeyValue_IgnoresOwnMessages(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	bus := &fakeInvalidationBus{}
+	a := cache.NewBroadcastingKeyValue(cache.NewInMemoryKeyValue(time.Minute, time.Minute), bus)
+
+	go a.Listen(ctx)
+	time.Sleep(10 * time.Millisecond)
+
+	if err := a.Set(ctx, "token", []byte("value")); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	// Give the (ignored) self-delivered message a chance to be mishandled.
+	time.Sleep(20 * time.Millisecond)
+
+	if _, err := a.Get(ctx, "token"); err != nil {
+		t.Errorf("expected own write to survive self-delivered invalidation, got %v", err)
+	}
+}
+
+func Test_BroadcastingKeyValue_RemoteInvalidateTag(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	bus := &fakeInvalidationBus{}
+	a := cache.NewBroadcastingKeyValue(cache.NewInMemoryKeyValue(time.Minute, time.Minute), bus)
+	bNode := cache.NewBroadcastingKeyValue(cache.NewInMemoryKeyValue(time.Minute, time.Minute), bus)
+
+	go a.Listen(ctx)
+	go bNode.Listen(ctx)
+	time.Sleep(10 * time.Millisecond)
+
+	if err := a.SetWithTags(ctx, "token", []byte("value"), []string{"user:1"}); err != nil {
+		t.Fatalf("SetWithTags: %v", err)
+	}
+	// A SetWithTags is never replicated to peers, so bNode needs its own
+	// tagged copy.
+	if err := bNode.SetWithTags(ctx, "token", []byte("value"), []string{"user:1"}); err != nil {
+		t.Fatalf("SetWithTags: %v", err)
+	}
+
+	waitForCondition(t, func() bool {
+		_, err := bNode.Get(ctx, "token")
+		return err == nil
+	})
+
+	if err := a.InvalidateTag(ctx, "user:1"); err != nil {
+		t.Fatalf("InvalidateTag: %v", err)
+	}
+
+	waitForCondition(t, func() bool {
+		_, err := bNode.Get(ctx, "token")
+		return errors.Is(err, cache.ErrCacheMiss)
+	})
+}
+
+func waitForCondition(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Fatalf("condition not met before deadline")
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec controls how TypedKeyValueCache turns values into bytes and back.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec is the default Codec; it preserves the historical behaviour of
+// TypedKeyValueCache.
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// GobCodec is a faster Codec for small Go-native structs (e.g. FileCountCache
+// or *int64) that don't need cross-language/backward-compat guarantees.
+type GobCodec struct{}
+
+func (GobCodec) Marshal(v any) ([]byte, error) {
+	// gob.Encode panics/errors on a nil pointer ("cannot encode nil pointer
+	// of type *T"), unlike JSONCodec which happily encodes it as null. Catch
+	// it here with a clear error instead of letting a future *int64/*struct
+	// caller hit that at runtime.
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil, fmt.Errorf("cache: GobCodec cannot marshal a nil %T", v)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (GobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// ProtoCodec marshals values that implement proto.Message.
+type ProtoCodec struct{}
+
+func (ProtoCodec) Marshal(v any) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("cache: ProtoCodec requires a proto.Message, got %T", v)
+	}
+
+	return proto.Marshal(msg)
+}
+
+func (ProtoCodec) Unmarshal(data []byte, v any) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("cache: ProtoCodec requires a proto.Message, got %T", v)
+	}
+
+	return proto.Unmarshal(data, msg)
+}
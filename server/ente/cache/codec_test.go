@@ -0,0 +1,52 @@
+package cache_test
+
+import (
+	"testing"
+
+	"github.com/ente-io/museum/ente/cache"
+)
+
+func Test_GobCodec_Marshal_NilPointer(t *testing.T) {
+	t.Parallel()
+
+	var v *int64
+	if _, err := (cache.GobCodec{}).Marshal(v); err == nil {
+		t.Fatalf("expected an error marshalling a nil pointer, got nil")
+	}
+}
+
+func BenchmarkJSONCodec_Int64(b *testing.B) {
+	benchmarkCodec(b, cache.JSONCodec{}, ptr(int64(42)))
+}
+
+func BenchmarkGobCodec_Int64(b *testing.B) {
+	benchmarkCodec(b, cache.GobCodec{}, ptr(int64(42)))
+}
+
+func BenchmarkJSONCodec_FileCountCache(b *testing.B) {
+	benchmarkCodec(b, cache.JSONCodec{}, &cache.FileCountCache{Count: 42, TrashUpdatedAt: 1, Usage: 1024})
+}
+
+func BenchmarkGobCodec_FileCountCache(b *testing.B) {
+	benchmarkCodec(b, cache.GobCodec{}, &cache.FileCountCache{Count: 42, TrashUpdatedAt: 1, Usage: 1024})
+}
+
+func benchmarkCodec(b *testing.B, codec cache.Codec, val any) {
+	b.Helper()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		raw, err := codec.Marshal(val)
+		if err != nil {
+			b.Fatalf("marshal: %v", err)
+		}
+
+		if err := codec.Unmarshal(raw, val); err != nil {
+			b.Fatalf("unmarshal: %v", err)
+		}
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,106 @@
+package cache_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ente-io/museum/ente/cache"
+)
+
+func Test_CompressedKeyValue_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		algo cache.CompressionAlgo
+	}{
+		{name: "gzip", algo: cache.CompressionGzip},
+		{name: "zstd", algo: cache.CompressionZstd},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+
+			inner := cache.NewInMemoryKeyValue(10*time.Minute, time.Minute)
+			kv := cache.NewCompressed(inner, cache.CompressionOptions{Algo: tt.algo, MinSize: 16})
+
+			large := []byte(strings.Repeat("x", 1024))
+			if err := kv.Set(ctx, "large", large); err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+
+			got, err := kv.Get(ctx, "large")
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if !bytes.Equal(large, got) {
+				t.Errorf("value mismatch: got %d bytes, want %d bytes", len(got), len(large))
+			}
+
+			small := []byte("tiny")
+			if err := kv.Set(ctx, "small", small); err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+			got, err = kv.Get(ctx, "small")
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if !bytes.Equal(small, got) {
+				t.Errorf("value mismatch: got %q, want %q", got, small)
+			}
+		})
+	}
+}
+
+func Test_CompressedKeyValue_LegacyUncompressedEntry(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	inner := cache.NewInMemoryKeyValue(10*time.Minute, time.Minute)
+	// Simulate an entry written before compression was rolled out: no codec
+	// tag prefix.
+	legacy := []byte(`{"hello":"world"}`)
+	if err := inner.Set(ctx, "legacy", legacy); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	kv := cache.NewCompressed(inner, cache.CompressionOptions{Algo: cache.CompressionGzip, MinSize: 16})
+
+	got, err := kv.Get(ctx, "legacy")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !bytes.Equal(legacy, got) {
+		t.Errorf("value mismatch: got %q, want %q", got, legacy)
+	}
+}
+
+func Test_CompressedKeyValue_WithPrefix(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	inner := cache.NewInMemoryKeyValue(10*time.Minute, time.Minute)
+	kv := cache.NewCompressed(inner, cache.CompressionOptions{Algo: cache.CompressionZstd, MinSize: 16}).WithPrefix("prefixed")
+
+	value := []byte(strings.Repeat("y", 1024))
+	if err := kv.Set(ctx, "key", value); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	got, err := kv.Get(ctx, "key")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !bytes.Equal(value, got) {
+		t.Errorf("value mismatch: got %d bytes, want %d bytes", len(got), len(value))
+	}
+
+	if _, err := inner.WithPrefix("prefixed").Get(ctx, "key"); err != nil {
+		t.Errorf("expected prefixed key to be stored on inner cache: %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package cache
 import (
 	"context"
 	"path"
+	"sync"
 	"time"
 
 	"github.com/patrickmn/go-cache"
@@ -11,20 +12,33 @@ import (
 var _ KeyValueCache = (*InMemoryKeyValue)(nil)
 
 func NewInMemoryKeyValue(defaultExpiration, cleanupInterval time.Duration) *InMemoryKeyValue {
+	tags := newTagIndex()
+	c := cache.New(defaultExpiration, cleanupInterval)
+	// Without this, tagIndex would keep a dead entry in tags[tag] forever
+	// once its underlying value expires or is evicted - unbounded growth for
+	// tags (like user:<id>) whose members are never all explicitly
+	// invalidated.
+	c.OnEvicted(func(key string, _ interface{}) {
+		tags.removeKey(key)
+	})
+
 	return &InMemoryKeyValue{
-		Cache: cache.New(defaultExpiration, cleanupInterval),
+		Cache: c,
+		tags:  tags,
 	}
 }
 
 type InMemoryKeyValue struct {
 	prefix string
 	Cache  *cache.Cache
+	tags   *tagIndex
 }
 
 func (i InMemoryKeyValue) WithPrefix(prefix string) KeyValueCache {
 	return InMemoryKeyValue{
 		prefix: path.Join(i.prefix, prefix),
 		Cache:  i.Cache,
+		tags:   i.tags,
 	}
 }
 
@@ -52,6 +66,120 @@ func (i InMemoryKeyValue) Unset(_ context.Context, key string) error {
 	return nil
 }
 
+func (i InMemoryKeyValue) GetOrLock(ctx context.Context, key string, lockTTL time.Duration) ([]byte, error) {
+	data, err := i.Get(ctx, key)
+	if err == nil {
+		return data, nil
+	}
+
+	if err := i.Cache.Add(i.lockKey(key), struct{}{}, lockTTL); err != nil {
+		return nil, ErrCacheKeyLocked
+	}
+
+	return nil, ErrCacheMiss
+}
+
+// SetWithTags implements KeyValueCache, recording key against each tag in
+// the shared in-process tagIndex.
+func (i InMemoryKeyValue) SetWithTags(ctx context.Context, key string, value []byte, tags []string) error {
+	if err := i.Set(ctx, key, value); err != nil {
+		return err
+	}
+
+	cacheKey := i.cacheKey(key)
+	for _, tag := range tags {
+		i.tags.add(tag, cacheKey)
+	}
+
+	return nil
+}
+
+// InvalidateTag implements KeyValueCache by deleting every key recorded
+// against tag in the shared in-process tagIndex.
+func (i InMemoryKeyValue) InvalidateTag(_ context.Context, tag string) error {
+	for _, key := range i.tags.popMembers(tag) {
+		i.Cache.Delete(key)
+	}
+
+	return nil
+}
+
 func (r InMemoryKeyValue) cacheKey(key string) string {
 	return path.Join(r.prefix, key)
 }
+
+func (r InMemoryKeyValue) lockKey(key string) string {
+	return r.cacheKey(key) + ":lock"
+}
+
+// tagIndex tracks which cache keys were tagged with which tags for
+// InMemoryKeyValue, guarded by a mutex since go-cache itself has no notion
+// of tags. It keeps a reverse index (key -> tags) so that removeKey, wired to
+// go-cache's eviction callback, can prune a single expired key out of every
+// tag it belonged to in O(tags for that key) instead of letting tags grow
+// unbounded for keys that are never explicitly invalidated.
+type tagIndex struct {
+	mu      sync.Mutex
+	members map[string]map[string]struct{} // tag -> keys
+	keyTags map[string]map[string]struct{} // key -> tags
+}
+
+func newTagIndex() *tagIndex {
+	return &tagIndex{
+		members: make(map[string]map[string]struct{}),
+		keyTags: make(map[string]map[string]struct{}),
+	}
+}
+
+func (t *tagIndex) add(tag, key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	members, ok := t.members[tag]
+	if !ok {
+		members = make(map[string]struct{})
+		t.members[tag] = members
+	}
+	members[key] = struct{}{}
+
+	keyTags, ok := t.keyTags[key]
+	if !ok {
+		keyTags = make(map[string]struct{})
+		t.keyTags[key] = keyTags
+	}
+	keyTags[tag] = struct{}{}
+}
+
+func (t *tagIndex) popMembers(tag string) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	members := t.members[tag]
+	delete(t.members, tag)
+
+	keys := make([]string, 0, len(members))
+	for key := range members {
+		keys = append(keys, key)
+		delete(t.keyTags[key], tag)
+		if len(t.keyTags[key]) == 0 {
+			delete(t.keyTags, key)
+		}
+	}
+
+	return keys
+}
+
+// removeKey drops key from every tag it was a member of. Called when the
+// underlying go-cache entry for key expires or is explicitly deleted.
+func (t *tagIndex) removeKey(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for tag := range t.keyTags[key] {
+		delete(t.members[tag], key)
+		if len(t.members[tag]) == 0 {
+			delete(t.members, tag)
+		}
+	}
+	delete(t.keyTags, key)
+}
@@ -0,0 +1,77 @@
+package cache_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ente-io/museum/ente/cache"
+)
+
+func Test_InMemoryKeyValue_InvalidateTag(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t)
+
+	kv := cache.NewInMemoryKeyValue(10*time.Minute, time.Minute).WithPrefix(t.Name())
+
+	if err := kv.SetWithTags(ctx, "a", []byte("1"), []string{"tag"}); err != nil {
+		t.Fatalf("SetWithTags: %v", err)
+	}
+	if err := kv.SetWithTags(ctx, "b", []byte("2"), []string{"tag"}); err != nil {
+		t.Fatalf("SetWithTags: %v", err)
+	}
+	if err := kv.Set(ctx, "c", []byte("3")); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if err := kv.InvalidateTag(ctx, "tag"); err != nil {
+		t.Fatalf("InvalidateTag: %v", err)
+	}
+
+	if _, err := kv.Get(ctx, "a"); !errors.Is(err, cache.ErrCacheMiss) {
+		t.Errorf("expected key a to be invalidated, got err %v", err)
+	}
+	if _, err := kv.Get(ctx, "b"); !errors.Is(err, cache.ErrCacheMiss) {
+		t.Errorf("expected key b to be invalidated, got err %v", err)
+	}
+
+	val, err := kv.Get(ctx, "c")
+	if err != nil {
+		t.Fatalf("expected untagged key c to survive, got err %v", err)
+	}
+	if !bytes.Equal(val, []byte("3")) {
+		t.Errorf("value %x, expected %x", val, []byte("3"))
+	}
+}
+
+// Test_InMemoryKeyValue_InvalidateTag_AcrossPrefixes mirrors how UserCache
+// tags fileCount/ and bonus/ entries with the same user tag off one root
+// cache: InvalidateTag must reach both sub-caches, not just the one it was
+// called on.
+func Test_InMemoryKeyValue_InvalidateTag_AcrossPrefixes(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t)
+
+	root := cache.NewInMemoryKeyValue(10*time.Minute, time.Minute).WithPrefix(t.Name())
+	fileCount := root.WithPrefix("fileCount")
+	bonus := root.WithPrefix("bonus")
+
+	if err := fileCount.SetWithTags(ctx, "1", []byte("42"), []string{"user:1"}); err != nil {
+		t.Fatalf("SetWithTags: %v", err)
+	}
+	if err := bonus.SetWithTags(ctx, "1", []byte("bonus"), []string{"user:1"}); err != nil {
+		t.Fatalf("SetWithTags: %v", err)
+	}
+
+	if err := fileCount.InvalidateTag(ctx, "user:1"); err != nil {
+		t.Fatalf("InvalidateTag: %v", err)
+	}
+
+	if _, err := fileCount.Get(ctx, "1"); !errors.Is(err, cache.ErrCacheMiss) {
+		t.Errorf("expected fileCount entry to be invalidated, got err %v", err)
+	}
+	if _, err := bonus.Get(ctx, "1"); !errors.Is(err, cache.ErrCacheMiss) {
+		t.Errorf("expected bonus entry in a different sub-cache to also be invalidated, got err %v", err)
+	}
+}
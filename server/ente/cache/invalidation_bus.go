@@ -0,0 +1,173 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// InvalidationOp describes what happened to a key on the publishing
+// instance. BroadcastingKeyValue.Listen only evicts locally on
+// InvalidationOpUnset (and tag messages, which are always eviction):
+// InvalidationOpSet just means a peer refreshed its own copy and implies
+// nothing about ours, so it's published for observability but otherwise
+// ignored by subscribers.
+type InvalidationOp string
+
+const (
+	InvalidationOpSet   InvalidationOp = "set"
+	InvalidationOpUnset InvalidationOp = "unset"
+)
+
+// InvalidationMessage is broadcast by BroadcastingKeyValue whenever a local
+// write happens, so peer instances can drop their own (now stale) copy.
+type InvalidationMessage struct {
+	InstanceID string `json:"instanceId"`
+	Prefix     string `json:"prefix"`
+	Key        string `json:"key"`
+	// Tag is set instead of Key for messages originating from InvalidateTag;
+	// subscribers invalidate the whole tag rather than a single key.
+	Tag string         `json:"tag,omitempty"`
+	Op  InvalidationOp `json:"op"`
+}
+
+// InvalidationBus is a pluggable pub/sub transport for InvalidationMessage.
+type InvalidationBus interface {
+	Publish(ctx context.Context, msg InvalidationMessage) error
+	// Subscribe returns a channel of incoming messages. The channel is
+	// closed when ctx is done. Implementations must be safe to keep
+	// delivering after transient transport errors (reconnect/resubscribe)
+	// and must never block a slow consumer indefinitely.
+	Subscribe(ctx context.Context) (<-chan InvalidationMessage, error)
+}
+
+// NoopInvalidationBus is the default for single-node deployments: nothing is
+// published, and subscribers never see a message.
+type NoopInvalidationBus struct{}
+
+func (NoopInvalidationBus) Publish(context.Context, InvalidationMessage) error {
+	return nil
+}
+
+func (NoopInvalidationBus) Subscribe(ctx context.Context) (<-chan InvalidationMessage, error) {
+	ch := make(chan InvalidationMessage)
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+
+	return ch, nil
+}
+
+const (
+	invalidationBusBufferSize   = 256
+	invalidationBusReconnectMin = 500 * time.Millisecond
+	invalidationBusReconnectMax = 10 * time.Second
+)
+
+// RedisInvalidationBus broadcasts InvalidationMessages over a Redis PUBLISH
+// channel, reconnecting and resubscribing on transport errors.
+type RedisInvalidationBus struct {
+	Client  *redis.Client
+	Channel string
+}
+
+func NewRedisInvalidationBus(client *redis.Client, channel string) *RedisInvalidationBus {
+	return &RedisInvalidationBus{Client: client, Channel: channel}
+}
+
+// Publish implements InvalidationBus.
+func (b *RedisInvalidationBus) Publish(ctx context.Context, msg InvalidationMessage) error {
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return b.Client.Publish(ctx, b.Channel, raw).Err()
+}
+
+// Subscribe implements InvalidationBus. The returned channel is bounded and
+// drops the oldest buffered message rather than blocking the deliverer - a
+// burst of revocations should never back-pressure HTTP handlers.
+func (b *RedisInvalidationBus) Subscribe(ctx context.Context) (<-chan InvalidationMessage, error) {
+	out := make(chan InvalidationMessage, invalidationBusBufferSize)
+
+	go b.subscribeLoop(ctx, out)
+
+	return out, nil
+}
+
+func (b *RedisInvalidationBus) subscribeLoop(ctx context.Context, out chan InvalidationMessage) {
+	defer close(out)
+
+	backoff := invalidationBusReconnectMin
+
+	for ctx.Err() == nil {
+		pubsub := b.Client.Subscribe(ctx, b.Channel)
+		msgCh := pubsub.Channel()
+		connected := time.Now()
+
+	readLoop:
+		for {
+			select {
+			case <-ctx.Done():
+				_ = pubsub.Close()
+				return
+			case rawMsg, ok := <-msgCh:
+				if !ok {
+					break readLoop
+				}
+
+				var msg InvalidationMessage
+				if err := json.Unmarshal([]byte(rawMsg.Payload), &msg); err != nil {
+					logrus.Warnf("cache: dropping malformed invalidation message: %s", err)
+					continue
+				}
+
+				deliverDropOldest(out, msg)
+			}
+		}
+
+		_ = pubsub.Close()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		// A connection that stayed up a while resets the backoff; one that
+		// died immediately backs off further before resubscribing.
+		if time.Since(connected) > invalidationBusReconnectMax {
+			backoff = invalidationBusReconnectMin
+		} else if backoff < invalidationBusReconnectMax {
+			backoff *= 2
+			if backoff > invalidationBusReconnectMax {
+				backoff = invalidationBusReconnectMax
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// deliverDropOldest sends msg without blocking; if out is full, the oldest
+// buffered message is dropped to make room.
+func deliverDropOldest(out chan InvalidationMessage, msg InvalidationMessage) {
+	for {
+		select {
+		case out <- msg:
+			return
+		default:
+			select {
+			case <-out:
+			default:
+			}
+		}
+	}
+}
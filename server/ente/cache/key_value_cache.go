@@ -3,13 +3,36 @@ package cache
 import (
 	"context"
 	"errors"
+	"time"
 )
 
-var ErrCacheMiss = errors.New("cache miss")
+var (
+	ErrCacheMiss = errors.New("cache miss")
+	// ErrCacheKeyLocked is returned by GetOrLock when another caller is
+	// already computing the value for a key.
+	ErrCacheKeyLocked = errors.New("cache key locked")
+)
 
 type KeyValueCache interface {
 	Get(ctx context.Context, key string) ([]byte, error)
 	Set(ctx context.Context, key string, value []byte) error
 	Unset(ctx context.Context, key string) error
 	WithPrefix(prefix string) KeyValueCache
+	// GetOrLock returns the cached value for key if one is present. If the
+	// key is missing it attempts to acquire a short-lived, side-channel lock
+	// for the caller so that at most one goroutine/process recomputes the
+	// value at a time:
+	//   - lock acquired: (nil, ErrCacheMiss) - the caller should compute the
+	//     value and populate the cache with Set
+	//   - lock already held: (nil, ErrCacheKeyLocked) - the caller should
+	//     back off and retry until the winner populates the entry or lockTTL
+	//     elapses
+	GetOrLock(ctx context.Context, key string, lockTTL time.Duration) ([]byte, error)
+	// SetWithTags behaves like Set but additionally associates key with each
+	// of tags, so that InvalidateTag(tag) can later drop every key tagged
+	// with it in one call.
+	SetWithTags(ctx context.Context, key string, value []byte, tags []string) error
+	// InvalidateTag deletes every key previously stored via SetWithTags with
+	// the given tag, and the tag's own bookkeeping entry.
+	InvalidateTag(ctx context.Context, tag string) error
 }
@@ -2,14 +2,19 @@ package cache_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/valkey"
+	"github.com/testcontainers/testcontainers-go/wait"
 )
 
 var (
-	valkeyContainer *valkey.ValkeyContainer
+	valkeyContainer    *valkey.ValkeyContainer
+	memcachedContainer testcontainers.Container
+	memcachedAddr      string
 )
 
 func TestMain(m *testing.M) {
@@ -25,11 +30,34 @@ func TestMain(m *testing.M) {
 		panic(err)
 	}
 
+	memcachedContainer, err = testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "docker.io/memcached:1.6-alpine",
+			ExposedPorts: []string{"11211/tcp"},
+			WaitingFor:   wait.ForListeningPort("11211/tcp"),
+		},
+		Started: true,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	memcachedHost, err := memcachedContainer.Host(ctx)
+	if err != nil {
+		panic(err)
+	}
+	memcachedPort, err := memcachedContainer.MappedPort(ctx, "11211")
+	if err != nil {
+		panic(err)
+	}
+	memcachedAddr = fmt.Sprintf("%s:%s", memcachedHost, memcachedPort.Port())
+
 	cancel()
 
 	m.Run()
 
 	ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
 	valkeyContainer.Terminate(ctx)
+	memcachedContainer.Terminate(ctx)
 	cancel()
 }
@@ -0,0 +1,200 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+)
+
+// memcachedMaxKeyLength is memcached's hard limit on key length.
+const memcachedMaxKeyLength = 250
+
+var _ KeyValueCache = (*MemcachedKeyValue)(nil)
+
+// NewMemcachedKeyValue creates a KeyValueCache backed by memcached, for
+// self-hosters that already run a memcached tier and don't want to add
+// Redis. The memcached server addresses are passed in as servers; this
+// package does not read them from config.
+func NewMemcachedKeyValue(defaultExpiration time.Duration, servers ...string) *MemcachedKeyValue {
+	return &MemcachedKeyValue{
+		TTL:    defaultExpiration,
+		Client: memcache.New(servers...),
+	}
+}
+
+type MemcachedKeyValue struct {
+	prefix string
+	Client *memcache.Client
+	TTL    time.Duration
+}
+
+// Get implements KeyValueCache.
+func (m *MemcachedKeyValue) Get(_ context.Context, key string) ([]byte, error) {
+	cacheKey := m.cacheKey(key)
+
+	item, err := m.Client.Get(cacheKey)
+	if err != nil {
+		if errors.Is(err, memcache.ErrCacheMiss) {
+			return nil, ErrCacheMiss
+		}
+
+		return nil, err
+	}
+
+	// Refresh the TTL on read, mirroring RedisKeyValue.Get's GetEx semantics.
+	_ = m.Client.Touch(cacheKey, int32(m.TTL.Seconds()))
+
+	return item.Value, nil
+}
+
+// Set implements KeyValueCache.
+func (m *MemcachedKeyValue) Set(_ context.Context, key string, value []byte) error {
+	return m.Client.Set(&memcache.Item{
+		Key:        m.cacheKey(key),
+		Value:      value,
+		Expiration: int32(m.TTL.Seconds()),
+	})
+}
+
+// Unset implements KeyValueCache.
+func (m *MemcachedKeyValue) Unset(_ context.Context, key string) error {
+	err := m.Client.Delete(m.cacheKey(key))
+	if err != nil && errors.Is(err, memcache.ErrCacheMiss) {
+		return nil
+	}
+
+	return err
+}
+
+// WithPrefix implements KeyValueCache.
+func (m *MemcachedKeyValue) WithPrefix(prefix string) KeyValueCache {
+	return &MemcachedKeyValue{
+		prefix: path.Join(m.prefix, prefix),
+		Client: m.Client,
+		TTL:    m.TTL,
+	}
+}
+
+// GetOrLock implements KeyValueCache.
+func (m *MemcachedKeyValue) GetOrLock(ctx context.Context, key string, lockTTL time.Duration) ([]byte, error) {
+	data, err := m.Get(ctx, key)
+	if err == nil {
+		return data, nil
+	}
+	if !errors.Is(err, ErrCacheMiss) {
+		return nil, err
+	}
+
+	err = m.Client.Add(&memcache.Item{
+		Key:        m.lockKey(key),
+		Value:      []byte("locked"),
+		Expiration: int32(lockTTL.Seconds()),
+	})
+	if err != nil {
+		if errors.Is(err, memcache.ErrNotStored) {
+			return nil, ErrCacheKeyLocked
+		}
+
+		return nil, err
+	}
+
+	return nil, ErrCacheMiss
+}
+
+// SetWithTags implements KeyValueCache. Memcached has no native set type, so
+// tag membership is kept as a newline-joined list under a synthetic
+// `tag/<name>` key shared by every WithPrefix derivation (so InvalidateTag
+// reaches all of them, as with RedisKeyValue and InMemoryKeyValue), updated with a best-effort (non-atomic) read-modify-write:
+// concurrent writers tagging the same tag can race, at worst leaving a stale
+// member that survives until its own TTL - acceptable for cache invalidation.
+func (m *MemcachedKeyValue) SetWithTags(ctx context.Context, key string, value []byte, tags []string) error {
+	if err := m.Set(ctx, key, value); err != nil {
+		return err
+	}
+
+	cacheKey := m.cacheKey(key)
+	for _, tag := range tags {
+		if err := m.addTagMember(tag, cacheKey); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// InvalidateTag implements KeyValueCache.
+func (m *MemcachedKeyValue) InvalidateTag(_ context.Context, tag string) error {
+	tagKey := m.tagKey(tag)
+
+	item, err := m.Client.Get(tagKey)
+	if err != nil {
+		if errors.Is(err, memcache.ErrCacheMiss) {
+			return nil
+		}
+
+		return err
+	}
+
+	for _, member := range strings.Split(string(item.Value), "\n") {
+		if member == "" {
+			continue
+		}
+		if err := m.Client.Delete(member); err != nil && !errors.Is(err, memcache.ErrCacheMiss) {
+			return err
+		}
+	}
+
+	if err := m.Client.Delete(tagKey); err != nil && !errors.Is(err, memcache.ErrCacheMiss) {
+		return err
+	}
+
+	return nil
+}
+
+func (m *MemcachedKeyValue) addTagMember(tag, cacheKey string) error {
+	tagKey := m.tagKey(tag)
+
+	members := cacheKey
+	if item, err := m.Client.Get(tagKey); err == nil {
+		members = string(item.Value) + "\n" + cacheKey
+	} else if !errors.Is(err, memcache.ErrCacheMiss) {
+		return err
+	}
+
+	return m.Client.Set(&memcache.Item{
+		Key:        tagKey,
+		Value:      []byte(members),
+		Expiration: int32(m.TTL.Seconds()),
+	})
+}
+
+func (m *MemcachedKeyValue) tagKey(tag string) string {
+	return memcachedSafeKey(path.Join("tag", tag))
+}
+
+func (m *MemcachedKeyValue) cacheKey(key string) string {
+	return memcachedSafeKey(path.Join(m.prefix, key))
+}
+
+func (m *MemcachedKeyValue) lockKey(key string) string {
+	return memcachedSafeKey(path.Join(m.prefix, key) + ":lock")
+}
+
+// memcachedSafeKey keeps a composed cache key under memcached's 250-byte key
+// limit, falling back to a SHA-256 hex digest for any key that would
+// otherwise overflow it.
+func memcachedSafeKey(key string) string {
+	if len(key) <= memcachedMaxKeyLength {
+		return key
+	}
+
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,183 @@
+package cache_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ente-io/museum/ente/cache"
+)
+
+func Test_MemcachedKeyValue_Get(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		seed    map[string][]byte
+		key     string
+		want    []byte
+		wantErr func(tb testing.TB, err error) error
+	}{
+		{
+			name:    "Cache empty",
+			key:     "hello",
+			wantErr: ignoreCacheMissError,
+		},
+		{
+			name: "No cache miss",
+			key:  "hello",
+			seed: map[string][]byte{
+				"hello": []byte("world"),
+			},
+			want:    []byte("world"),
+			wantErr: wantNoError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := testContext(t)
+
+			memcachedKV := cache.NewMemcachedKeyValue(10*time.Minute, memcachedAddr)
+			kv := memcachedKV.WithPrefix(t.Name())
+
+			if tt.seed != nil {
+				for k, v := range tt.seed {
+					kv.Set(ctx, k, v)
+				}
+			}
+
+			raw, err := kv.Get(ctx, tt.key)
+			if err := tt.wantErr(t, err); err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+
+			if !bytes.Equal(tt.want, raw) {
+				t.Errorf("value %x, expected %x", raw, tt.want)
+			}
+		})
+	}
+}
+
+func Test_MemcachedKeyValue_Set(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		key   string
+		value []byte
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		seed    map[string][]byte
+		want    map[string][]byte
+		wantErr func(tb testing.TB, err error) error
+	}{
+		{
+			name: "Empty cache",
+			args: args{
+				key:   "hello",
+				value: []byte("world"),
+			},
+			want: map[string][]byte{
+				"hello": []byte("world"),
+			},
+			wantErr: wantNoError,
+		},
+		{
+			name: "Override existing value",
+			args: args{
+				key:   "hello",
+				value: []byte("world"),
+			},
+			seed: map[string][]byte{
+				"hello": []byte("go"),
+			},
+			want: map[string][]byte{
+				"hello": []byte("world"),
+			},
+			wantErr: wantNoError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := testContext(t)
+
+			memcachedKV := cache.NewMemcachedKeyValue(10*time.Minute, memcachedAddr)
+			kv := memcachedKV.WithPrefix(t.Name())
+
+			if tt.seed != nil {
+				for k, v := range tt.seed {
+					kv.Set(ctx, k, v)
+				}
+			}
+
+			if err := tt.wantErr(t, kv.Set(ctx, tt.args.key, tt.args.value)); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			for k, v := range tt.want {
+				val, err := kv.Get(ctx, k)
+				if err := tt.wantErr(t, err); err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+
+				if !bytes.Equal(v, val) {
+					t.Errorf("want %x, got %x", v, val)
+				}
+			}
+		})
+	}
+}
+
+func Test_MemcachedKeyValue_GetOrLock(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t)
+
+	memcachedKV := cache.NewMemcachedKeyValue(10*time.Minute, memcachedAddr)
+	kv := memcachedKV.WithPrefix(t.Name())
+
+	data, err := kv.GetOrLock(ctx, "token", time.Second)
+	if !errors.Is(err, cache.ErrCacheMiss) {
+		t.Fatalf("expected ErrCacheMiss for the winner, got %v", err)
+	}
+	if data != nil {
+		t.Errorf("expected no data for the winner, got %x", data)
+	}
+
+	_, err = kv.GetOrLock(ctx, "token", time.Second)
+	if !errors.Is(err, cache.ErrCacheKeyLocked) {
+		t.Fatalf("expected ErrCacheKeyLocked for the loser, got %v", err)
+	}
+}
+
+func Test_MemcachedKeyValue_KeyOverflow(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t)
+
+	memcachedKV := cache.NewMemcachedKeyValue(10*time.Minute, memcachedAddr)
+	kv := memcachedKV.WithPrefix(t.Name())
+
+	longKey := strings.Repeat("k", 512)
+	value := []byte("world")
+
+	if err := kv.Set(ctx, longKey, value); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	got, err := kv.Get(ctx, longKey)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !bytes.Equal(value, got) {
+		t.Errorf("value %x, expected %x", got, value)
+	}
+}
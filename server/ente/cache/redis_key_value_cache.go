@@ -30,8 +30,16 @@ func NewRedisKeyValue(defaultExpiration time.Duration, connString string) (*Redi
 
 type RedisKeyValue struct {
 	prefix string
-	Client *redis.Client
-	TTL    time.Duration
+	// tagRoot is the prefix in effect when this RedisKeyValue tree was
+	// constructed, fixed at that point and carried unchanged through every
+	// WithPrefix derivation - unlike prefix, which keeps accumulating. Tags
+	// are looked up under tagRoot rather than prefix so that InvalidateTag
+	// reaches every sub-cache derived from the same root (e.g. UserCache's
+	// fileCount/ and bonus/ caches), matching InMemoryKeyValue's tagIndex,
+	// which is shared (unprefixed) across all of its WithPrefix derivations.
+	tagRoot string
+	Client  *redis.Client
+	TTL     time.Duration
 }
 
 // Get implements KeyValueCache.
@@ -59,12 +67,77 @@ func (r *RedisKeyValue) Unset(ctx context.Context, key string) error {
 	return cmd.Err()
 }
 
+// GetOrLock implements KeyValueCache.
+func (r *RedisKeyValue) GetOrLock(ctx context.Context, key string, lockTTL time.Duration) ([]byte, error) {
+	data, err := r.Get(ctx, key)
+	if err == nil {
+		return data, nil
+	}
+	if !errors.Is(err, ErrCacheMiss) {
+		return nil, err
+	}
+
+	acquired, err := r.Client.SetNX(ctx, r.lockKey(key), "locked", lockTTL).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, ErrCacheKeyLocked
+	}
+
+	return nil, ErrCacheMiss
+}
+
+// SetWithTags implements KeyValueCache, maintaining a `tag/<name>` Redis set
+// (under tagRoot) of member keys for each tag via SADD. The tag set itself is
+// left without a TTL: Get refreshes a hot member's own TTL on every read
+// (GetEx), so a fixed TTL on the tag set could expire out from under a member
+// that's still very much alive, making a later InvalidateTag silently miss it.
+// Members are never removed from the set individually; the whole set, expired
+// members included, is only dropped by InvalidateTag.
+func (r *RedisKeyValue) SetWithTags(ctx context.Context, key string, value []byte, tags []string) error {
+	cacheKey := r.cacheKey(key)
+
+	pipe := r.Client.TxPipeline()
+	pipe.SetEx(ctx, cacheKey, value, r.TTL)
+	for _, tag := range tags {
+		pipe.SAdd(ctx, r.tagKey(tag), cacheKey)
+	}
+	_, err := pipe.Exec(ctx)
+
+	return err
+}
+
+// InvalidateTag implements KeyValueCache via SMEMBERS+DEL+DEL tag/<name>.
+// SMEMBERS can include members that already expired on their own TTL without
+// ever being explicitly invalidated; deleting an already-gone key is a
+// harmless no-op, so no extra existence check is needed before the DEL.
+func (r *RedisKeyValue) InvalidateTag(ctx context.Context, tag string) error {
+	tagKey := r.tagKey(tag)
+
+	members, err := r.Client.SMembers(ctx, tagKey).Result()
+	if err != nil {
+		return err
+	}
+	if len(members) == 0 {
+		return r.Client.Del(ctx, tagKey).Err()
+	}
+
+	pipe := r.Client.TxPipeline()
+	pipe.Del(ctx, members...)
+	pipe.Del(ctx, tagKey)
+	_, err = pipe.Exec(ctx)
+
+	return err
+}
+
 // WithPrefix implements KeyValueCache.
 func (r *RedisKeyValue) WithPrefix(prefix string) KeyValueCache {
 	return &RedisKeyValue{
-		prefix: path.Join(r.prefix, prefix),
-		Client: r.Client,
-		TTL:    r.TTL,
+		prefix:  path.Join(r.prefix, prefix),
+		tagRoot: r.tagRoot,
+		Client:  r.Client,
+		TTL:     r.TTL,
 	}
 }
 
@@ -75,3 +148,11 @@ func (r *RedisKeyValue) Close() error {
 func (r *RedisKeyValue) cacheKey(key string) string {
 	return path.Join(r.prefix, key)
 }
+
+func (r *RedisKeyValue) lockKey(key string) string {
+	return r.cacheKey(key) + ":lock"
+}
+
+func (r *RedisKeyValue) tagKey(tag string) string {
+	return path.Join(r.tagRoot, "tag", tag)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"path"
 	"testing"
 	"time"
 
@@ -166,6 +167,153 @@ func Test_RedisKeyValue_Set(t *testing.T) {
 	}
 }
 
+func Test_RedisKeyValue_InvalidateTag(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t)
+
+	connString, err := valkeyContainer.ConnectionString(ctx)
+	if err != nil {
+		t.Fatalf("failed to get Redis connection string: %v", err)
+	}
+
+	redisKV, err := cache.NewRedisKeyValue(10*time.Minute, connString)
+	if err != nil {
+		t.Fatalf("failed to create Redis KV: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := redisKV.Close(); err != nil {
+			t.Errorf("failed to close Redis connection: %v", err)
+		}
+	})
+
+	kv := redisKV.WithPrefix(t.Name())
+	// Tags live in a single namespace shared by every sub-cache of a root
+	// RedisKeyValue (see tagRoot), which in this test suite means every
+	// parallel test sharing valkeyContainer - so the tag name itself, not
+	// just the key prefix, needs to be test-scoped.
+	tag := t.Name() + "-tag"
+
+	if err := kv.SetWithTags(ctx, "a", []byte("1"), []string{tag}); err != nil {
+		t.Fatalf("SetWithTags: %v", err)
+	}
+	if err := kv.SetWithTags(ctx, "b", []byte("2"), []string{tag}); err != nil {
+		t.Fatalf("SetWithTags: %v", err)
+	}
+	if err := kv.Set(ctx, "c", []byte("3")); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if err := kv.InvalidateTag(ctx, tag); err != nil {
+		t.Fatalf("InvalidateTag: %v", err)
+	}
+
+	if _, err := kv.Get(ctx, "a"); !errors.Is(err, cache.ErrCacheMiss) {
+		t.Errorf("expected key a to be invalidated, got err %v", err)
+	}
+	if _, err := kv.Get(ctx, "b"); !errors.Is(err, cache.ErrCacheMiss) {
+		t.Errorf("expected key b to be invalidated, got err %v", err)
+	}
+
+	val, err := kv.Get(ctx, "c")
+	if err != nil {
+		t.Fatalf("expected untagged key c to survive, got err %v", err)
+	}
+	if !bytes.Equal(val, []byte("3")) {
+		t.Errorf("value %x, expected %x", val, []byte("3"))
+	}
+}
+
+// Test_RedisKeyValue_SetWithTags_TagSetHasNoTTL guards against reintroducing
+// a TTL on the tag/<name> set. Get refreshes a member's own TTL on every read
+// (GetEx) without ever touching the tag set, so a hot, continuously-read
+// member can easily outlive any fixed TTL put on its tag - which would make
+// a later InvalidateTag see an empty SMEMBERS and silently miss it.
+func Test_RedisKeyValue_SetWithTags_TagSetHasNoTTL(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t)
+
+	connString, err := valkeyContainer.ConnectionString(ctx)
+	if err != nil {
+		t.Fatalf("failed to get Redis connection string: %v", err)
+	}
+
+	redisKV, err := cache.NewRedisKeyValue(10*time.Minute, connString)
+	if err != nil {
+		t.Fatalf("failed to create Redis KV: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := redisKV.Close(); err != nil {
+			t.Errorf("failed to close Redis connection: %v", err)
+		}
+	})
+
+	kv := redisKV.WithPrefix(t.Name())
+	tag := t.Name() + "-tag"
+
+	if err := kv.SetWithTags(ctx, "a", []byte("1"), []string{tag}); err != nil {
+		t.Fatalf("SetWithTags: %v", err)
+	}
+
+	// tagKey mirrors RedisKeyValue.tagKey's unexported layout: tagRoot/tag/<name>,
+	// and tagRoot is empty here since WithPrefix doesn't affect it.
+	tagKey := path.Join("tag", tag)
+
+	ttl, err := redisKV.Client.TTL(ctx, tagKey).Result()
+	if err != nil {
+		t.Fatalf("TTL: %v", err)
+	}
+	if ttl != -1 {
+		t.Fatalf("expected tag set to have no expiry (TTL -1), got %v", ttl)
+	}
+}
+
+// Test_RedisKeyValue_InvalidateTag_AcrossPrefixes mirrors how UserCache tags
+// fileCount/ and bonus/ entries with the same user tag off one root cache:
+// InvalidateTag must reach every sub-cache derived from that root, not just
+// the one it was called through.
+func Test_RedisKeyValue_InvalidateTag_AcrossPrefixes(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t)
+
+	connString, err := valkeyContainer.ConnectionString(ctx)
+	if err != nil {
+		t.Fatalf("failed to get Redis connection string: %v", err)
+	}
+
+	redisKV, err := cache.NewRedisKeyValue(10*time.Minute, connString)
+	if err != nil {
+		t.Fatalf("failed to create Redis KV: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := redisKV.Close(); err != nil {
+			t.Errorf("failed to close Redis connection: %v", err)
+		}
+	})
+
+	root := redisKV.WithPrefix(t.Name())
+	fileCount := root.WithPrefix("fileCount")
+	bonus := root.WithPrefix("bonus")
+	tag := t.Name() + "-user:1"
+
+	if err := fileCount.SetWithTags(ctx, "1", []byte("42"), []string{tag}); err != nil {
+		t.Fatalf("SetWithTags: %v", err)
+	}
+	if err := bonus.SetWithTags(ctx, "1", []byte("bonus"), []string{tag}); err != nil {
+		t.Fatalf("SetWithTags: %v", err)
+	}
+
+	if err := fileCount.InvalidateTag(ctx, tag); err != nil {
+		t.Fatalf("InvalidateTag: %v", err)
+	}
+
+	if _, err := fileCount.Get(ctx, "1"); !errors.Is(err, cache.ErrCacheMiss) {
+		t.Errorf("expected fileCount entry to be invalidated, got err %v", err)
+	}
+	if _, err := bonus.Get(ctx, "1"); !errors.Is(err, cache.ErrCacheMiss) {
+		t.Errorf("expected bonus entry in a different sub-cache to also be invalidated, got err %v", err)
+	}
+}
+
 func wantNoError(tb testing.TB, err error) error {
 	tb.Helper()
 	return err
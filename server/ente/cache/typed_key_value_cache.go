@@ -2,17 +2,28 @@ package cache
 
 import (
 	"context"
-	"encoding/json"
+	"time"
 )
 
+// NewTypedKeyValueCache creates a TypedKeyValueCache using JSONCodec, the
+// historical default.
 func NewTypedKeyValueCache[V any](cache KeyValueCache) TypedKeyValueCache[V] {
+	return NewTypedKeyValueCacheWithCodec[V](cache, JSONCodec{})
+}
+
+// NewTypedKeyValueCacheWithCodec creates a TypedKeyValueCache that marshals
+// values using the given Codec, e.g. GobCodec for hot paths that round-trip
+// small Go-native structs.
+func NewTypedKeyValueCacheWithCodec[V any](cache KeyValueCache, codec Codec) TypedKeyValueCache[V] {
 	return TypedKeyValueCache[V]{
 		Cache: cache,
+		Codec: codec,
 	}
 }
 
 type TypedKeyValueCache[V any] struct {
 	Cache KeyValueCache
+	Codec Codec
 }
 
 func (c TypedKeyValueCache[V]) Get(ctx context.Context, key string) (val V, err error) {
@@ -21,7 +32,7 @@ func (c TypedKeyValueCache[V]) Get(ctx context.Context, key string) (val V, err
 		return val, err
 	}
 
-	if err = json.Unmarshal(raw, val); err != nil {
+	if err = c.Codec.Unmarshal(raw, &val); err != nil {
 		return val, err
 	}
 
@@ -29,7 +40,7 @@ func (c TypedKeyValueCache[V]) Get(ctx context.Context, key string) (val V, err
 }
 
 func (c TypedKeyValueCache[V]) Set(ctx context.Context, key string, val V) error {
-	raw, err := json.Marshal(val)
+	raw, err := c.Codec.Marshal(val)
 	if err != nil {
 		return err
 	}
@@ -40,3 +51,60 @@ func (c TypedKeyValueCache[V]) Set(ctx context.Context, key string, val V) error
 func (c TypedKeyValueCache[V]) Unset(ctx context.Context, key string) error {
 	return c.Cache.Unset(ctx, key)
 }
+
+// SetWithTags mirrors KeyValueCache.SetWithTags, encoding val with the
+// configured Codec.
+func (c TypedKeyValueCache[V]) SetWithTags(ctx context.Context, key string, val V, tags []string) error {
+	raw, err := c.Codec.Marshal(val)
+	if err != nil {
+		return err
+	}
+
+	return c.Cache.SetWithTags(ctx, key, raw, tags)
+}
+
+// InvalidateTag mirrors KeyValueCache.InvalidateTag.
+func (c TypedKeyValueCache[V]) InvalidateTag(ctx context.Context, tag string) error {
+	return c.Cache.InvalidateTag(ctx, tag)
+}
+
+// GetOrLock mirrors KeyValueCache.GetOrLock, decoding a cache hit into V.
+// A (zero value, ErrCacheMiss) result means the caller acquired the lock and
+// is expected to compute the value and populate the cache with Set; a
+// (zero value, ErrCacheKeyLocked) result means someone else is already doing
+// so.
+func (c TypedKeyValueCache[V]) GetOrLock(ctx context.Context, key string, lockTTL time.Duration) (val V, err error) {
+	raw, err := c.Cache.GetOrLock(ctx, key, lockTTL)
+	if err != nil {
+		return val, err
+	}
+
+	if err = c.Codec.Unmarshal(raw, &val); err != nil {
+		return val, err
+	}
+
+	return val, nil
+}
+
+// WaitForValue polls Get every interval until it returns a value or deadline
+// elapses, returning ErrCacheMiss if nothing showed up in time. It is meant
+// for callers that lost the race in GetOrLock and are waiting for the winner
+// to populate the entry.
+func WaitForValue[V any](ctx context.Context, c TypedKeyValueCache[V], key string, interval, deadline time.Duration) (val V, err error) {
+	timeout := time.After(deadline)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return val, ctx.Err()
+		case <-timeout:
+			return val, ErrCacheMiss
+		case <-ticker.C:
+			if val, err = c.Get(ctx, key); err == nil {
+				return val, nil
+			}
+		}
+	}
+}
@@ -22,20 +22,35 @@ type FileCountCache struct {
 }
 
 // NewUserCache creates a new instance of the UserCache struct.
+// fileCache defaults to GobCodec since FileCountCache is a small Go-native
+// struct on a hot path; bonusCache keeps JSONCodec.
 func NewUserCache(cache KeyValueCache) *UserCache {
 	return &UserCache{
-		fileCache:  NewTypedKeyValueCache[*FileCountCache](cache.WithPrefix("fileCount/")),
+		fileCache:  NewTypedKeyValueCacheWithCodec[*FileCountCache](cache.WithPrefix("fileCount/"), GobCodec{}),
 		bonusCache: NewTypedKeyValueCache[*storagebonus.ActiveStorageBonus](cache.WithPrefix("bonus/")),
 	}
 }
 
 // SetFileCount updates the fileCount with the given userID and fileCount.
 func (c *UserCache) SetFileCount(ctx context.Context, userID int64, fileCount *FileCountCache, app ente.App) error {
-	return c.fileCache.Set(ctx, cacheKey(userID, app), fileCount)
+	return c.fileCache.SetWithTags(ctx, cacheKey(userID, app), fileCount, []string{UserTag(userID)})
 }
 
 func (c *UserCache) SetBonus(ctx context.Context, userID int64, bonus *storagebonus.ActiveStorageBonus) error {
-	return c.bonusCache.Set(ctx, strconv.FormatInt(userID, 10), bonus)
+	return c.bonusCache.SetWithTags(ctx, strconv.FormatInt(userID, 10), bonus, []string{UserTag(userID)})
+}
+
+// InvalidateUser drops every cached surface for userID (file count, storage
+// bonus) in one call, so a revoked/logged-out user doesn't see stale data
+// served from cache until TTL.
+func (c *UserCache) InvalidateUser(ctx context.Context, userID int64) error {
+	tag := UserTag(userID)
+
+	if err := c.fileCache.InvalidateTag(ctx, tag); err != nil {
+		return err
+	}
+
+	return c.bonusCache.InvalidateTag(ctx, tag)
 }
 
 func (c *UserCache) GetBonus(ctx context.Context, userID int64) (*storagebonus.ActiveStorageBonus, bool) {
@@ -61,3 +76,9 @@ func (c *UserCache) GetFileCount(ctx context.Context, userID int64, app ente.App
 func cacheKey(userID int64, app ente.App) string {
 	return fmt.Sprintf("%d-%s", userID, app)
 }
+
+// UserTag is the cache tag applied to every cache entry keyed by userID, so
+// InvalidateTag can drop all of a user's cached surfaces in one call.
+func UserTag(userID int64) string {
+	return fmt.Sprintf("user:%d", userID)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -21,9 +22,19 @@ import (
 	"github.com/ente-io/museum/pkg/utils/auth"
 )
 
+const (
+	// tokenCacheLockTTL bounds how long a winner may hold the per-token
+	// singleflight lock before losers give up waiting and fall through to
+	// the slow path themselves.
+	tokenCacheLockTTL          = 5 * time.Second
+	tokenCacheLockSpinInterval = 20 * time.Millisecond
+)
+
 // AuthMiddleware intercepts and authenticates incoming requests
 type AuthMiddleware struct {
-	UserAuthRepo   *repo.UserAuthRepository
+	UserAuthRepo *repo.UserAuthRepository
+	// Cache should be constructed with cache.GobCodec - this is a per-request
+	// hot path that only ever round-trips a *int64.
 	Cache          cache.TypedKeyValueCache[*int64]
 	UserController *user.UserController
 }
@@ -46,7 +57,13 @@ func (m *AuthMiddleware) TokenAuthMiddleware(jwtClaimScope *jwt.ClaimScope) gin.
 			isJWT = true
 			cacheKey = fmt.Sprintf("%s:%s:%s", app, token, *jwtClaimScope)
 		}
-		rawUserID, err := m.Cache.Get(c, cacheKey)
+		rawUserID, err := m.Cache.GetOrLock(c, cacheKey, tokenCacheLockTTL)
+		if errors.Is(err, cache.ErrCacheKeyLocked) {
+			// Someone else is already computing this token; spin until they
+			// populate the entry or the lock expires, then fall through to
+			// the slow path ourselves.
+			rawUserID, err = cache.WaitForValue(c, m.Cache, cacheKey, tokenCacheLockSpinInterval, tokenCacheLockTTL)
+		}
 
 		if err != nil {
 			var userID int64
@@ -75,7 +92,7 @@ func (m *AuthMiddleware) TokenAuthMiddleware(jwtClaimScope *jwt.ClaimScope) gin.
 				}
 			}
 			rawUserID = &userID
-			_ = m.Cache.Set(c, cacheKey, rawUserID)
+			_ = m.Cache.SetWithTags(c, cacheKey, rawUserID, []string{cache.UserTag(userID)})
 		}
 		c.Request.Header.Set("X-Auth-User-ID", strconv.FormatInt(*rawUserID, 10))
 		c.Next()
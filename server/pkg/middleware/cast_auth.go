@@ -1,8 +1,10 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -12,8 +14,18 @@ import (
 	"github.com/ente-io/museum/pkg/utils/auth"
 )
 
+const (
+	// castCacheLockTTL bounds how long a winner may hold the per-token
+	// singleflight lock before losers give up waiting and fall through to
+	// the slow path themselves.
+	castCacheLockTTL          = 5 * time.Second
+	castCacheLockSpinInterval = 20 * time.Millisecond
+)
+
 // CastMiddleware intercepts and authenticates incoming requests
 type CastMiddleware struct {
+	// Cache should be constructed with cache.GobCodec - this is a
+	// per-request hot path that only ever round-trips a *cast.AuthContext.
 	Cache    cache2.TypedKeyValueCache[*cast.AuthContext]
 	CastCtrl *castCtrl.Controller
 }
@@ -31,7 +43,14 @@ func (m *CastMiddleware) CastAuthMiddleware() gin.HandlerFunc {
 		}
 		app := auth.GetApp(c)
 		cacheKey := fmt.Sprintf("%s:%s:%s", app, token, "cast")
-		cachedCastCtx, err := m.Cache.Get(c, cacheKey)
+		cachedCastCtx, err := m.Cache.GetOrLock(c, cacheKey, castCacheLockTTL)
+		if errors.Is(err, cache2.ErrCacheKeyLocked) {
+			// Someone else is already resolving this cast token; spin until
+			// they populate the entry or the lock expires, then fall through
+			// to the slow path ourselves.
+			cachedCastCtx, err = cache2.WaitForValue(c, m.Cache, cacheKey, castCacheLockSpinInterval, castCacheLockTTL)
+		}
+
 		if err != nil {
 			castCtx, err := m.CastCtrl.GetCollectionAndCasterIDForToken(c, token)
 			if err != nil {